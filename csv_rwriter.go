@@ -0,0 +1,127 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// csvColumns is the fixed column order of a CSV batch file:
+// from,to,value,data,passphrase,gas.
+const csvColumns = 6
+
+// CSVRWriter reads batch entries from a CSV file one record at a time via
+// encoding/csv.Reader, which never holds more than a single record in
+// memory. Results are buffered and written to a sibling "<file>.result.csv"
+// on Flush.
+type CSVRWriter struct {
+	file   *os.File
+	reader *csv.Reader
+	idx    int
+
+	mu      sync.Mutex
+	results map[int]map[string]string
+
+	resultPath string
+}
+
+// NewCSVRWriter opens a CSV batch file for streaming reads. The file is
+// expected to have no header row: from,to,value,data,passphrase,gas.
+func NewCSVRWriter(path string) (*CSVRWriter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = csvColumns
+	return &CSVRWriter{
+		file:       file,
+		reader:     reader,
+		results:    make(map[int]map[string]string),
+		resultPath: path + ".result.csv",
+	}, nil
+}
+
+// Next reads and returns the next entry, or io.EOF once the file is
+// exhausted.
+func (w *CSVRWriter) Next() (*Entry, error) {
+	record, err := w.reader.Read()
+	if err != nil {
+		return nil, err
+	}
+	value, err := strconv.ParseInt(record[2], 10, 64)
+	if err != nil {
+		return nil, err
+	}
+	var gas uint64
+	if record[5] != "" {
+		if gas, err = strconv.ParseUint(record[5], 10, 64); err != nil {
+			return nil, err
+		}
+	}
+	entry := &Entry{
+		From:       common.HexToAddress(record[0]),
+		To:         common.HexToAddress(record[1]),
+		Value:      value,
+		Data:       record[3],
+		Passphrase: record[4],
+		Gas:        gas,
+	}
+	w.idx++
+	return entry, nil
+}
+
+// WriteResult buffers a named result field for entry idx.
+func (w *CSVRWriter) WriteResult(idx int, field, value string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	row, ok := w.results[idx]
+	if !ok {
+		row = make(map[string]string)
+		w.results[idx] = row
+	}
+	row[field] = value
+	return nil
+}
+
+// Flush writes the buffered results to "<file>.result.csv" and closes the
+// input file.
+func (w *CSVRWriter) Flush() error {
+	defer w.file.Close()
+
+	out, err := os.Create(w.resultPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	writer := csv.NewWriter(out)
+	defer writer.Flush()
+	for idx, row := range w.results {
+		writer.Write([]string{
+			strconv.Itoa(idx),
+			row[resultHash],
+			row[resultStatus],
+			row[resultContract],
+		})
+	}
+	return writer.Error()
+}
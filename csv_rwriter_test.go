@@ -0,0 +1,73 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func writeCSV(t *testing.T, contents string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "batch.csv")
+	if err := os.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatalf("WriteFile: %v", err)
+	}
+	return path
+}
+
+func TestCSVRWriterNext(t *testing.T) {
+	path := writeCSV(t, "0x1,0x2,100,0xabcd,secret,21000\n0x3,0x4,200,,,\n")
+	rw, err := NewCSVRWriter(path)
+	if err != nil {
+		t.Fatalf("NewCSVRWriter: %v", err)
+	}
+	defer rw.file.Close()
+
+	entry, err := rw.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if entry.Value != 100 || entry.Gas != 21000 || entry.Data != "0xabcd" || entry.Passphrase != "secret" {
+		t.Fatalf("unexpected entry: %+v", entry)
+	}
+
+	entry, err = rw.Next()
+	if err != nil {
+		t.Fatalf("Next: %v", err)
+	}
+	if entry.Value != 200 || entry.Gas != 0 {
+		t.Fatalf("unexpected entry with blank gas: %+v", entry)
+	}
+
+	if _, err := rw.Next(); err != io.EOF {
+		t.Fatalf("Next at end = %v, want io.EOF", err)
+	}
+}
+
+func TestCSVRWriterNextInvalidValue(t *testing.T) {
+	path := writeCSV(t, "0x1,0x2,not-a-number,,,\n")
+	rw, err := NewCSVRWriter(path)
+	if err != nil {
+		t.Fatalf("NewCSVRWriter: %v", err)
+	}
+	defer rw.file.Close()
+
+	if _, err := rw.Next(); err == nil {
+		t.Fatal("Next with non-numeric value = <nil>, want error")
+	}
+}
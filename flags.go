@@ -0,0 +1,101 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"time"
+
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Flags controlling EIP-1559 dynamic-fee transaction construction and the
+// automatic fee-bumping replacement loop.
+var (
+	txTypeFlag = cli.StringFlag{
+		Name:  "tx-type",
+		Usage: "Transaction type to construct: legacy, dynamic or auto",
+		Value: txTypeLegacy,
+	}
+	tipCapFlag = cli.StringFlag{
+		Name:  "tip-cap",
+		Usage: "Max priority fee per gas in wei, used by dynamic and auto tx types",
+	}
+	feeCapFlag = cli.StringFlag{
+		Name:  "fee-cap",
+		Usage: "Max fee per gas in wei, used by dynamic and auto tx types",
+	}
+	replaceTxFlag = cli.BoolFlag{
+		Name:  "replace-tx",
+		Usage: "Rebroadcast the transaction with bumped fees if it is not mined within replace-interval",
+	}
+	replaceIntervalFlag = cli.DurationFlag{
+		Name:  "replace-interval",
+		Usage: "How long to wait before rebroadcasting an unmined transaction",
+		Value: 30 * time.Second,
+	}
+	replaceBumpPercentFlag = cli.IntFlag{
+		Name:  "replace-bump-percent",
+		Usage: "Percentage to bump tip cap and fee cap (or gas price for legacy transactions) by on each rebroadcast",
+		Value: 10,
+	}
+	replaceTimeoutFlag = cli.DurationFlag{
+		Name:  "replace-timeout",
+		Usage: "Hard timeout after which replace-tx gives up rebroadcasting",
+		Value: 10 * time.Minute,
+	}
+)
+
+// Flags controlling the concurrent batch-sending pipeline.
+var (
+	concurrencyFlag = cli.IntFlag{
+		Name:  "concurrency",
+		Usage: "Number of worker goroutines signing and submitting batch transactions in parallel",
+		Value: 4,
+	}
+	paramCacheTTLFlag = cli.DurationFlag{
+		Name:  "param-cache-ttl",
+		Usage: "How long cached chain id and gas price are reused before being refetched",
+		Value: 30 * time.Second,
+	}
+)
+
+// signerExternalFlag selects the external (Clef-style) signer backend,
+// mutually exclusive with --keystore/--passphrase.
+var signerExternalFlag = cli.StringFlag{
+	Name:  "signer.external",
+	Usage: "URL of an external Clef-style signer to use instead of a local keystore",
+}
+
+// formatFlag overrides batch file format auto-detection.
+var formatFlag = cli.StringFlag{
+	Name:  "format",
+	Usage: "Batch file format: xlsx, json, csv or text (default: auto-detect by extension)",
+}
+
+// Flags controlling how long and how thoroughly sendTransaction waits for
+// a transaction to be mined.
+var (
+	waitTimeoutFlag = cli.DurationFlag{
+		Name:  "wait.timeout",
+		Usage: "Maximum time to wait for a transaction to reach the required confirmations",
+		Value: 60 * time.Second,
+	}
+	waitConfirmationsFlag = cli.IntFlag{
+		Name:  "wait.confirmations",
+		Usage: "Number of block confirmations to require before a wait is considered successful",
+		Value: 1,
+	}
+)
+
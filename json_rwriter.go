@@ -0,0 +1,151 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+var errInvalidJSONValue = errors.New("invalid value field in json batch entry")
+
+// jsonEntry is the on-disk shape of one row of a JSON batch file:
+// {"from":..,"to":..,"value":..,"data":..,"passphrase":..,"gas":..,"nonce":..,"type":..}.
+// value accepts either a 0x-prefixed hex string or a plain decimal string.
+type jsonEntry struct {
+	From       string  `json:"from"`
+	To         string  `json:"to"`
+	Value      string  `json:"value"`
+	Data       string  `json:"data"`
+	Passphrase string  `json:"passphrase"`
+	Gas        uint64  `json:"gas"`
+	Nonce      *uint64 `json:"nonce"`
+	Type       string  `json:"type"`
+}
+
+func (je jsonEntry) toEntry() (*Entry, error) {
+	value, err := parseJSONValue(je.Value)
+	if err != nil {
+		return nil, err
+	}
+	return &Entry{
+		From:       common.HexToAddress(je.From),
+		To:         common.HexToAddress(je.To),
+		Value:      value,
+		Data:       je.Data,
+		Passphrase: je.Passphrase,
+		Gas:        je.Gas,
+		Nonce:      je.Nonce,
+		Type:       je.Type,
+	}, nil
+}
+
+// parseJSONValue accepts either a 0x-prefixed hex string or a plain decimal
+// string for a JSON entry's value field.
+func parseJSONValue(value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+	if strings.HasPrefix(value, "0x") || strings.HasPrefix(value, "0X") {
+		parsed, ok := new(big.Int).SetString(value[2:], 16)
+		if !ok {
+			return 0, errInvalidJSONValue
+		}
+		return parsed.Int64(), nil
+	}
+	return strconv.ParseInt(value, 10, 64)
+}
+
+// JSONRWriter reads batch entries from a JSON array one element at a time
+// via a streaming decoder, so a multi-GB JSON batch file is never held in
+// memory in full. Results are buffered and written to a sibling
+// "<file>.result.json" on Flush, since appending to the middle of a JSON
+// array in place isn't possible while streaming.
+type JSONRWriter struct {
+	file *os.File
+	dec  *json.Decoder
+
+	mu      sync.Mutex
+	results map[int]map[string]string
+
+	resultPath string
+}
+
+// NewJSONRWriter opens a JSON batch file for streaming reads.
+func NewJSONRWriter(path string) (*JSONRWriter, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(file)
+	if _, err := dec.Token(); err != nil { // consume the opening '['
+		file.Close()
+		return nil, err
+	}
+	return &JSONRWriter{
+		file:       file,
+		dec:        dec,
+		results:    make(map[int]map[string]string),
+		resultPath: path + ".result.json",
+	}, nil
+}
+
+// Next decodes and returns the next entry, or io.EOF once the array is
+// exhausted.
+func (w *JSONRWriter) Next() (*Entry, error) {
+	if !w.dec.More() {
+		return nil, io.EOF
+	}
+	var je jsonEntry
+	if err := w.dec.Decode(&je); err != nil {
+		return nil, err
+	}
+	return je.toEntry()
+}
+
+// WriteResult buffers a named result field for entry idx.
+func (w *JSONRWriter) WriteResult(idx int, field, value string) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	row, ok := w.results[idx]
+	if !ok {
+		row = make(map[string]string)
+		w.results[idx] = row
+	}
+	row[field] = value
+	return nil
+}
+
+// Flush writes the buffered results to "<file>.result.json" and closes the
+// input file.
+func (w *JSONRWriter) Flush() error {
+	defer w.file.Close()
+
+	out, err := os.Create(w.resultPath)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+	return json.NewEncoder(out).Encode(w.results)
+}
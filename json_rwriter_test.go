@@ -0,0 +1,49 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import "testing"
+
+func TestParseJSONValue(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    int64
+		wantErr bool
+	}{
+		{value: "", want: 0},
+		{value: "0", want: 0},
+		{value: "1000", want: 1000},
+		{value: "0x3e8", want: 1000},
+		{value: "0X3E8", want: 1000},
+		{value: "0xzz", wantErr: true},
+		{value: "not-a-number", wantErr: true},
+	}
+	for _, test := range tests {
+		got, err := parseJSONValue(test.value)
+		if test.wantErr {
+			if err == nil {
+				t.Errorf("parseJSONValue(%q) = %d, <nil>, want error", test.value, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseJSONValue(%q) returned error: %v", test.value, err)
+			continue
+		}
+		if got != test.want {
+			t.Errorf("parseJSONValue(%q) = %d, want %d", test.value, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"io"
+	"strconv"
+)
+
+// legacyRWriter is the ReadAll/WriteString/Flush shape that ExcelRWriter and
+// RawTextRWriter were built against before the batch format was made
+// pluggable. Both still implement it unchanged; legacyRWriterAdapter is
+// what lets them satisfy the newer streaming RWriter interface.
+type legacyRWriter interface {
+	ReadAll() ([]Entry, error)
+	WriteString(axis, value string) error
+	Flush() error
+}
+
+// legacyRWriterAdapter exposes a legacyRWriter through the streaming
+// RWriter interface. It calls ReadAll once up front, so xlsx and plain-text
+// batches are buffered in memory rather than streamed like
+// CSVRWriter/JSONRWriter are -- unavoidable for xlsx (the format isn't
+// seekable row by row) and harmless for the two formats' typical use as
+// small, hand-edited batches. axis translates a (idx, field) result into
+// the cell/line address WriteString expects, returning ok=false for a
+// field the underlying format has no room for (e.g. plain text only has a
+// line index, not spare columns for status/contract).
+type legacyRWriterAdapter struct {
+	rw   legacyRWriter
+	axis func(idx int, field string) (string, bool)
+
+	entries []Entry
+	idx     int
+}
+
+// newLegacyRWriterAdapter reads all entries out of rw up front and wraps it
+// for streaming-style consumption.
+func newLegacyRWriterAdapter(rw legacyRWriter, axis func(idx int, field string) (string, bool)) (*legacyRWriterAdapter, error) {
+	entries, err := rw.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	return &legacyRWriterAdapter{rw: rw, axis: axis, entries: entries}, nil
+}
+
+// Next returns the next buffered entry, or io.EOF once they're exhausted.
+func (a *legacyRWriterAdapter) Next() (*Entry, error) {
+	if a.idx >= len(a.entries) {
+		return nil, io.EOF
+	}
+	entry := a.entries[a.idx]
+	a.idx++
+	return &entry, nil
+}
+
+// WriteResult writes a named result field via the wrapped legacyRWriter's
+// axis-addressed WriteString, silently dropping fields axis has no room
+// for.
+func (a *legacyRWriterAdapter) WriteResult(idx int, field, value string) error {
+	axis, ok := a.axis(idx, field)
+	if !ok {
+		return nil
+	}
+	return a.rw.WriteString(axis, value)
+}
+
+// Flush flushes the wrapped legacyRWriter.
+func (a *legacyRWriterAdapter) Flush() error {
+	return a.rw.Flush()
+}
+
+// excelResultAxis maps a result field to the spreadsheet cell that holds
+// it: column F for the hash, G for status, H for contract, matching the
+// original "F"+idx+2 axis convention (row idx+2 to account for the header
+// row).
+func excelResultAxis(idx int, field string) (string, bool) {
+	row := strconv.Itoa(idx + 2)
+	switch field {
+	case resultHash:
+		return "F" + row, true
+	case resultStatus:
+		return "G" + row, true
+	case resultContract:
+		return "H" + row, true
+	default:
+		return "", false
+	}
+}
+
+// rawTextResultAxis mirrors the original plain-text behaviour: only the
+// transaction hash is recorded, addressed by line index. A raw text batch
+// has no spare column to hold status or contract results.
+func rawTextResultAxis(idx int, field string) (string, bool) {
+	if field != resultHash {
+		return "", false
+	}
+	return strconv.Itoa(idx), true
+}
@@ -0,0 +1,28 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	_ "unsafe" // for go:linkname
+)
+
+// monoNow returns a monotonic nanosecond clock reading, the same one the
+// Go runtime itself uses for timers. Deadlines computed from it can't be
+// pushed back (or tripped early) by an NTP step that moves time.Now, unlike
+// wall-clock-based deadlines used elsewhere for short per-RPC timeouts.
+// This mirrors the linkname trick the Arista monotime package uses.
+//
+//go:linkname monoNow runtime.nanotime
+func monoNow() int64
@@ -0,0 +1,128 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/rjl493456442/ethclient/client"
+)
+
+// nonceManager hands out monotonically increasing nonces for senders taking
+// part in a batch send. The nonce for a sender is seeded once from the
+// node's pending nonce and then incremented locally, so a batch doesn't pay
+// an RPC round-trip per transaction just to stay in order.
+type nonceManager struct {
+	client *client.Client
+	mu     sync.Mutex
+	nonces map[common.Address]uint64
+}
+
+// newNonceManager creates a nonce manager backed by the given client.
+func newNonceManager(client *client.Client) *nonceManager {
+	return &nonceManager{
+		client: client,
+		nonces: make(map[common.Address]uint64),
+	}
+}
+
+// next returns the next nonce to use for sender, seeding it from
+// PendingNonceAt the first time the sender is seen.
+func (m *nonceManager) next(sender common.Address) (uint64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	nonce, ok := m.nonces[sender]
+	if !ok {
+		timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+		pending, err := m.client.Cli.PendingNonceAt(timeoutContext, sender)
+		if err != nil {
+			return 0, err
+		}
+		nonce = pending
+	}
+	m.nonces[sender] = nonce + 1
+	return nonce, nil
+}
+
+// release gives back a nonce that was reserved but never actually
+// submitted, so the next caller for the same sender reuses it instead of
+// leaving a gap.
+func (m *nonceManager) release(sender common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if current, ok := m.nonces[sender]; ok && current == nonce+1 {
+		m.nonces[sender] = nonce
+	}
+}
+
+// paramCache caches the chain id and suggested gas price for a configurable
+// TTL, so a large batch doesn't refetch them for every single entry.
+type paramCache struct {
+	client *client.Client
+	ttl    time.Duration
+
+	mu          sync.Mutex
+	chainId     *big.Int
+	chainIdAt   time.Time
+	gasPrice    *big.Int
+	gasPriceAt  time.Time
+}
+
+// newParamCache creates a param cache backed by the given client with the
+// given TTL.
+func newParamCache(client *client.Client, ttl time.Duration) *paramCache {
+	return &paramCache{client: client, ttl: ttl}
+}
+
+// ChainID returns the cached chain id, refetching it once the cache entry
+// has expired.
+func (c *paramCache) ChainID() (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.chainId != nil && time.Since(c.chainIdAt) < c.ttl {
+		return c.chainId, nil
+	}
+	timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+	chainId, err := c.client.Cli.NetworkID(timeoutContext)
+	if err != nil {
+		return nil, err
+	}
+	c.chainId, c.chainIdAt = chainId, time.Now()
+	return chainId, nil
+}
+
+// GasPrice returns the cached suggested gas price, refetching it once the
+// cache entry has expired.
+func (c *paramCache) GasPrice() (*big.Int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.gasPrice != nil && time.Since(c.gasPriceAt) < c.ttl {
+		return c.gasPrice, nil
+	}
+	timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+	gasPrice, err := c.client.Cli.SuggestGasPrice(timeoutContext)
+	if err != nil {
+		return nil, err
+	}
+	c.gasPrice, c.gasPriceAt = gasPrice, time.Now()
+	return gasPrice, nil
+}
@@ -0,0 +1,73 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestNonceManagerNextReusesSeededNonce(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	nm := &nonceManager{nonces: map[common.Address]uint64{sender: 5}}
+
+	nonce, err := nm.next(sender)
+	if err != nil {
+		t.Fatalf("next returned error: %v", err)
+	}
+	if nonce != 5 {
+		t.Fatalf("next = %d, want 5", nonce)
+	}
+	nonce, err = nm.next(sender)
+	if err != nil {
+		t.Fatalf("next returned error: %v", err)
+	}
+	if nonce != 6 {
+		t.Fatalf("next = %d, want 6", nonce)
+	}
+}
+
+func TestNonceManagerReleaseRewindsLastReservation(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	nm := &nonceManager{nonces: map[common.Address]uint64{sender: 5}}
+
+	nonce, err := nm.next(sender)
+	if err != nil {
+		t.Fatalf("next returned error: %v", err)
+	}
+	nm.release(sender, nonce)
+
+	next, err := nm.next(sender)
+	if err != nil {
+		t.Fatalf("next returned error: %v", err)
+	}
+	if next != nonce {
+		t.Fatalf("next after release = %d, want %d", next, nonce)
+	}
+}
+
+func TestNonceManagerReleaseIgnoresStaleNonce(t *testing.T) {
+	sender := common.HexToAddress("0x1")
+	nm := &nonceManager{nonces: map[common.Address]uint64{sender: 7}}
+
+	// A release for a nonce that's no longer the most recent reservation
+	// (e.g. a retry racing a later call) must not rewind the counter.
+	nm.release(sender, 3)
+
+	if got := nm.nonces[sender]; got != 7 {
+		t.Fatalf("nonces[sender] = %d, want 7", got)
+	}
+}
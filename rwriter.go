@@ -0,0 +1,96 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// Entry is a single row of a batch file: a transaction to send plus the
+// bookkeeping fields (passphrase, optional explicit gas/nonce/type) that
+// let a batch mix raw calldata and macro rows freely.
+type Entry struct {
+	From       common.Address
+	To         common.Address
+	Value      int64
+	Data       string
+	Passphrase string
+	Gas        uint64
+	Nonce      *uint64
+	Type       string
+}
+
+// Result field names accepted by RWriter.WriteResult.
+const (
+	resultHash     = "hash"
+	resultStatus   = "status"
+	resultContract = "contract"
+)
+
+// RWriter streams entries out of a batch file and records results for them,
+// so a multi-GB CSV or JSON batch never has to be held in memory in full. Next
+// returns io.EOF once the file is exhausted. WriteResult records a named
+// result field (resultHash, resultStatus, resultContract) for the entry at
+// idx; implementations that have no room for a given field (e.g. plain
+// text) silently ignore it.
+type RWriter interface {
+	Next() (*Entry, error)
+	WriteResult(idx int, field, value string) error
+	Flush() error
+}
+
+// newRWriter opens batchfile with the format requested by --format, falling
+// back to auto-detection by file extension.
+func newRWriter(ctx *cli.Context, batchfile string) (RWriter, error) {
+	format := ctx.String(formatFlag.Name)
+	if format == "" {
+		format = detectFormat(batchfile)
+	}
+	switch format {
+	case "xlsx":
+		legacy, err := NewExcelRWriter(batchfile, getSheetId(ctx))
+		if err != nil {
+			return nil, err
+		}
+		return newLegacyRWriterAdapter(legacy, excelResultAxis)
+	case "json":
+		return NewJSONRWriter(batchfile)
+	case "csv":
+		return NewCSVRWriter(batchfile)
+	default:
+		legacy, err := NewRawTextRWriter(batchfile)
+		if err != nil {
+			return nil, err
+		}
+		return newLegacyRWriterAdapter(legacy, rawTextResultAxis)
+	}
+}
+
+// detectFormat guesses a batch file's format from its extension.
+func detectFormat(batchfile string) string {
+	switch {
+	case strings.HasSuffix(batchfile, ".xlsx"):
+		return "xlsx"
+	case strings.HasSuffix(batchfile, ".json"):
+		return "json"
+	case strings.HasSuffix(batchfile, ".csv"):
+		return "csv"
+	default:
+		return "text"
+	}
+}
@@ -17,15 +17,15 @@ package main
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
 	"math/big"
 	"os"
-	"strconv"
-	"strings"
+	"sync"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts"
-	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/rjl493456442/ethclient/client"
@@ -36,8 +36,60 @@ var (
 	errInvalidArguments  = errors.New("invalid transaction or call arguments")
 	errWaitTimeout       = errors.New("wait transaction mined timeout")
 	errInvalidBatchIndex = errors.New("invalid batch index")
+	errUnknownTxType     = errors.New("unknown transaction type")
+	errNoBaseFee         = errors.New("chain does not report a base fee; pass --fee-cap or use --tx-type auto")
+	errNoCodeAfterDeploy = errors.New("no contract code after deployment")
 )
 
+// Supported values for the --tx-type flag.
+const (
+	txTypeLegacy  = "legacy"
+	txTypeDynamic = "dynamic"
+	txTypeAuto    = "auto"
+)
+
+// txOptions bundles the fee-related flags so they don't have to be threaded
+// through sendTransaction as a long, ever-growing argument list.
+type txOptions struct {
+	txType   string
+	tipCap   *big.Int
+	feeCap   *big.Int
+	gasPrice *big.Int // overrides callMsg.GasPrice for a legacy rebroadcast; see bumpedTxOptions
+
+	replace         bool
+	replaceInterval time.Duration
+	replaceBumpPct  int64
+	replaceTimeout  time.Duration
+
+	waitTimeout   time.Duration
+	confirmations uint64
+}
+
+// newTxOptions parses the fee and replace-tx flags out of the cli context.
+func newTxOptions(ctx *cli.Context) (*txOptions, error) {
+	opts := &txOptions{
+		txType:          ctx.String(txTypeFlag.Name),
+		replace:         ctx.Bool(replaceTxFlag.Name),
+		replaceInterval: ctx.Duration(replaceIntervalFlag.Name),
+		replaceBumpPct:  int64(ctx.Int(replaceBumpPercentFlag.Name)),
+		replaceTimeout:  ctx.Duration(replaceTimeoutFlag.Name),
+		waitTimeout:     ctx.Duration(waitTimeoutFlag.Name),
+		confirmations:   uint64(ctx.Int(waitConfirmationsFlag.Name)),
+	}
+	switch opts.txType {
+	case txTypeLegacy, txTypeDynamic, txTypeAuto:
+	default:
+		return nil, errUnknownTxType
+	}
+	if tip := ctx.String(tipCapFlag.Name); tip != "" {
+		opts.tipCap, _ = new(big.Int).SetString(tip, 10)
+	}
+	if fee := ctx.String(feeCapFlag.Name); fee != "" {
+		opts.feeCap, _ = new(big.Int).SetString(fee, 10)
+	}
+	return opts, nil
+}
+
 var commandSend = cli.Command{
 	Name:        "send",
 	Usage:       "Send transaction to ethereum network",
@@ -52,6 +104,16 @@ var commandSend = cli.Command{
 		valueFlag,
 		dataFlag,
 		syncFlag,
+		txTypeFlag,
+		tipCapFlag,
+		feeCapFlag,
+		replaceTxFlag,
+		replaceIntervalFlag,
+		replaceBumpPercentFlag,
+		replaceTimeoutFlag,
+		signerExternalFlag,
+		waitTimeoutFlag,
+		waitConfirmationsFlag,
 	},
 	Action: Send,
 }
@@ -69,10 +131,31 @@ var commandSendBatch = cli.Command{
 		batchIndexBeginFlag,
 		batchIndexEndFlag,
 		tokenfileFlag,
+		concurrencyFlag,
+		paramCacheTTLFlag,
+		signerExternalFlag,
+		formatFlag,
+		waitTimeoutFlag,
+		waitConfirmationsFlag,
 	},
 	Action: SendBatch,
 }
 
+// batchJob is a single entry streamed out of the batch file, tagged with
+// its position so results can be written back to the right row.
+type batchJob struct {
+	idx   int
+	entry *Entry
+}
+
+// batchResult is what a batch worker reports back for a processed job.
+type batchResult struct {
+	idx      int
+	hash     common.Hash
+	isCreate bool
+	err      error
+}
+
 // Send sends a transaction with specified fields.
 func Send(ctx *cli.Context) error {
 	var (
@@ -103,154 +186,504 @@ func Send(ctx *cli.Context) error {
 	if err != nil {
 		return err
 	}
-	keystore := getKeystore(ctx)
+	signer, err := getSigner(ctx)
+	if err != nil {
+		return err
+	}
+
+	opts, err := newTxOptions(ctx)
+	if err != nil {
+		return err
+	}
 
-	_, err = sendTransaction(client, callMsg, passphrase, keystore, ctx.Bool(syncFlag.Name))
-	return err
+	hash, contractAddr, err := sendTransaction(client, callMsg, passphrase, signer, ctx.Bool(syncFlag.Name), opts)
+	if err != nil {
+		return err
+	}
+	if contractAddr != (common.Address{}) {
+		logger.Noticef("sendTransaction, hash=%s, contractAddr=%s", hash.Hex(), contractAddr.Hex())
+	}
+	return nil
 }
 
 // SendBatch sends a batch of specified transactions to ethereum server.
 func SendBatch(ctx *cli.Context) error {
-	var (
-		batchfile = getBatchFile(ctx)
-		rw        RWriter
-		err       error
-		begin     int
-		end       int
-	)
+	batchfile := getBatchFile(ctx)
 	if _, err := os.Stat(batchfile); os.IsNotExist(err) {
 		return err
 	}
-
-	switch strings.HasSuffix(batchfile, ".xlsx") {
-	case true:
-		rw, err = NewExcelRWriter(batchfile, getSheetId(ctx))
-	default:
-		rw, err = NewRawTextRWriter(batchfile)
-	}
-	if err != nil {
-		return err
-	}
-	entries, err := rw.ReadAll()
+	rw, err := newRWriter(ctx, batchfile)
 	if err != nil {
 		return err
 	}
 	// Read begin, end index for batch file
-	begin, end = ctx.Int(batchIndexBeginFlag.Name), ctx.Int(batchIndexEndFlag.Name)
-	if end == 0 {
-		end = len(entries)
-	}
-
-	if begin >= end {
+	begin, end := ctx.Int(batchIndexBeginFlag.Name), ctx.Int(batchIndexEndFlag.Name)
+	if end != 0 && begin >= end {
 		return errInvalidBatchIndex
 	}
 
-	entries = entries[begin:end]
 	// Setup rpc client
 	client, err := getClient(ctx)
 	if err != nil {
 		return err
 	}
-	keystore := getKeystore(ctx)
+	signer, err := getSigner(ctx)
+	if err != nil {
+		return err
+	}
 
 	mp, err := getMacroParser(client, ctx.String(tokenfileFlag.Name))
 	if err != nil {
 		return err
 	}
 
-	for idx, entry := range entries {
-		// Construct call message
-		if !CheckArguments(entry.From.Hex(), entry.To.Hex(), int(entry.Value), []byte(entry.Data)) {
-			return errInvalidArguments
-		}
-		var data string	= entry.Data
-		var to common.Address = entry.To
-		if mp.isMacroDefinition(data) {
-			to, data, _, err = mp.Parse(data, entry.From.Hex(), entry.To.Hex())
+	var (
+		nm            = newNonceManager(client)
+		pc            = newParamCache(client, ctx.Duration(paramCacheTTLFlag.Name))
+		concurrency   = ctx.Int(concurrencyFlag.Name)
+		waitTimeout   = ctx.Duration(waitTimeoutFlag.Name)
+		confirmations = uint64(ctx.Int(waitConfirmationsFlag.Name))
+	)
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	jobs := make(chan batchJob)
+	results := make(chan batchResult)
+	receiptJobs := make(chan batchResult)
+
+	var workers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for job := range jobs {
+				results <- sendBatchEntry(client, signer, mp, nm, pc, ctx, job)
+			}
+		}()
+	}
+	// receiptWatchers share a small, concurrency-bounded pool rather than one
+	// goroutine (and one head subscription) per batch entry, so a large
+	// batch doesn't open thousands of concurrent subscriptions against the
+	// node.
+	var receiptWatchers sync.WaitGroup
+	for i := 0; i < concurrency; i++ {
+		receiptWatchers.Add(1)
+		go func() {
+			defer receiptWatchers.Done()
+			for res := range receiptJobs {
+				watchBatchReceipt(client, rw, res, waitTimeout, confirmations)
+			}
+		}()
+	}
+	go func() {
+		defer close(jobs)
+		for idx := 0; end == 0 || idx < end; idx++ {
+			entry, err := rw.Next()
+			if err == io.EOF {
+				return
+			}
 			if err != nil {
 				logger.Error(err)
+				return
+			}
+			if idx < begin {
 				continue
 			}
+			jobs <- batchJob{idx: idx, entry: entry}
+		}
+	}()
+	go func() {
+		workers.Wait()
+		close(results)
+	}()
+
+	for res := range results {
+		if res.err != nil {
+			logger.Error(res.err)
+			continue
+		}
+		if err := rw.WriteResult(res.idx, resultHash, res.hash.Hex()); err != nil {
+			logger.Error(err)
 		}
+		receiptJobs <- res
+	}
+	close(receiptJobs)
+	receiptWatchers.Wait()
+	rw.Flush()
+	return nil
+}
 
-		callMsg := &ethereum.CallMsg{
-			From:  entry.From,
-			To:    &to,
-			Value: big.NewInt(entry.Value),
-			Data:  common.FromHex(data),
+// sendBatchEntry resolves the macro, builds the call message and submits a
+// single batch entry, pulling its nonce and gas price from the shared nonce
+// manager and param cache instead of refetching them per entry.
+func sendBatchEntry(client *client.Client, signer Signer, mp *macroParser, nm *nonceManager, pc *paramCache, ctx *cli.Context, job batchJob) batchResult {
+	entry := job.entry
+	result := batchResult{idx: job.idx}
+
+	if !CheckArguments(entry.From.Hex(), entry.To.Hex(), int(entry.Value), []byte(entry.Data)) {
+		result.err = errInvalidArguments
+		return result
+	}
+	var (
+		data string         = entry.Data
+		to   common.Address = entry.To
+		err  error
+	)
+	if mp.isMacroDefinition(data) {
+		to, data, _, err = mp.Parse(data, entry.From.Hex(), entry.To.Hex())
+		if err != nil {
+			result.err = err
+			return result
 		}
+	}
+
+	callMsg := &ethereum.CallMsg{
+		From:  entry.From,
+		To:    &to,
+		Value: big.NewInt(entry.Value),
+		Data:  common.FromHex(data),
+	}
+	if entry.To.Hex() == "" {
+		callMsg.To = nil
+	}
+	result.isCreate = callMsg.To == nil
+	passphrase := entry.Passphrase
+	if passphrase == "" {
+		passphrase = getPassphrase(ctx, false)
+	}
 
-		if entry.To.Hex() == "" {
-			callMsg.To = nil
+	result.hash, result.err = sendBatchTransaction(client, callMsg, passphrase, signer, nm, pc, entry)
+	return result
+}
+
+// sendBatchTransaction mirrors sendTransaction but draws its nonce, gas
+// price and chain id from the batch's shared nonce manager and param cache
+// rather than issuing four RPC round-trips per transaction. An entry may
+// pin an explicit gas limit and/or nonce, skipping estimation for that
+// field.
+func sendBatchTransaction(client *client.Client, callMsg *ethereum.CallMsg, passphrase string, signer Signer, nm *nonceManager, pc *paramCache, entry *Entry) (common.Hash, error) {
+	gasLimit := entry.Gas
+	if gasLimit == 0 {
+		timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+		estimated, err := client.Cli.EstimateGas(timeoutContext, *callMsg)
+		if err != nil {
+			return common.Hash{}, err
 		}
-		if entry.Passphrase == "" {
-			entry.Passphrase = getPassphrase(ctx, false)
+		gasLimit = estimated
+	}
+	gasPrice, err := pc.GasPrice()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	chainId, err := pc.ChainID()
+	if err != nil {
+		return common.Hash{}, err
+	}
+	var nonce uint64
+	if entry.Nonce != nil {
+		nonce = *entry.Nonce
+	} else if nonce, err = nm.next(callMsg.From); err != nil {
+		return common.Hash{}, err
+	}
+	callMsg.Gas = gasLimit
+	callMsg.GasPrice = gasPrice
+
+	txType, err := entryTxType(entry)
+	if err != nil {
+		nm.release(callMsg.From, nonce)
+		return common.Hash{}, err
+	}
+	tx, err := buildTransaction(client, callMsg, nonce, chainId, &txOptions{txType: txType})
+	if err != nil {
+		nm.release(callMsg.From, nonce)
+		return common.Hash{}, err
+	}
+	tx, err = signer.SignTxWithPassphrase(accounts.Account{Address: callMsg.From}, passphrase, tx, chainId)
+	if err != nil {
+		nm.release(callMsg.From, nonce)
+		return common.Hash{}, err
+	}
+	sendContext, _ := makeTimeoutContext(5 * time.Second)
+	if err := client.Cli.SendTransaction(sendContext, tx); err != nil {
+		nm.release(callMsg.From, nonce)
+		return common.Hash{}, err
+	}
+	logger.Noticef("sendBatchTransaction, hash=%s", tx.Hash().Hex())
+	return tx.Hash(), nil
+}
+
+// entryTxType returns the transaction type a batch entry's optional "type"
+// field requests, defaulting to legacy for the formats (xlsx, csv, text)
+// that have no column for it.
+func entryTxType(entry *Entry) (string, error) {
+	switch entry.Type {
+	case "", txTypeLegacy, txTypeDynamic, txTypeAuto:
+	default:
+		return "", errUnknownTxType
+	}
+	if entry.Type == "" {
+		return txTypeLegacy, nil
+	}
+	return entry.Type, nil
+}
+
+// watchBatchReceipt waits for the batch entry's transaction to be mined and
+// records its block number/status back into the sheet. Callers run it from
+// a bounded pool of receipt watchers rather than one goroutine per entry, so
+// a batch never holds more than a handful of head subscriptions open at
+// once. It is best-effort: a failed wait is logged and leaves the entry
+// without a status rather than failing the batch, though SendBatch still
+// waits for every watcher to finish before flushing results.
+func watchBatchReceipt(client *client.Client, rw RWriter, res batchResult, waitTimeout time.Duration, confirmations uint64) {
+	receipt, err := waitMined(context.Background(), client, res.hash, waitTimeout, confirmations)
+	if err != nil {
+		logger.Error(err)
+		return
+	}
+	status := fmt.Sprintf("block=%d status=%d", receipt.BlockNumber.Uint64(), receipt.Status)
+	if err := rw.WriteResult(res.idx, resultStatus, status); err != nil {
+		logger.Error(err)
+	}
+	if res.isCreate {
+		contractAddr, err := waitDeployed(context.Background(), client, receipt)
+		if err != nil {
+			logger.Error(err)
+			return
 		}
-		// Never wait during the batch sending
-		if hash, err := sendTransaction(client, callMsg, entry.Passphrase, keystore, false); err != nil {
+		if err := rw.WriteResult(res.idx, resultContract, contractAddr.Hex()); err != nil {
 			logger.Error(err)
-			continue
-		} else {
-			// Record the hash to batch file
-			var (
-				actualIdx = idx + begin
-				axis      string
-			)
-			switch rw.(type) {
-			case *ExcelRWriter:
-				axis = "F" + strconv.Itoa(actualIdx+2)
-			case *RawTextRWriter:
-				axis = strconv.Itoa(actualIdx)
-			}
-			err = rw.WriteString(axis, hash.Hex())
-			if err != nil {
-				logger.Error(err)
-			}
 		}
 	}
-	rw.Flush()
-	return nil
 }
 
-// sendTransaction sends a transaction with given call message and fill with sufficient fields like account nonce.
-func sendTransaction(client *client.Client, callMsg *ethereum.CallMsg, passphrase string, keystore *keystore.KeyStore, wait bool) (common.Hash, error) {
+// sendTransaction sends a transaction with given call message and fill with
+// sufficient fields like account nonce. When wait is set and the call
+// message creates a contract, it also confirms the code was actually
+// deployed and returns the contract address.
+func sendTransaction(client *client.Client, callMsg *ethereum.CallMsg, passphrase string, signer Signer, wait bool, opts *txOptions) (common.Hash, common.Address, error) {
+	if opts == nil {
+		opts = &txOptions{txType: txTypeLegacy}
+	}
 	gasPrice, gasLimit, nonce, chainId, err := fetchParams(client, callMsg)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, common.Address{}, err
 	}
-	var tx *types.Transaction
 	callMsg.Gas = gasLimit
 	callMsg.GasPrice = gasPrice
 
-	if callMsg.To == nil {
-		tx = types.NewContractCreation(nonce, callMsg.Value, callMsg.Gas, callMsg.GasPrice, callMsg.Data)
-	} else {
-		tx = types.NewTransaction(nonce, *callMsg.To, callMsg.Value, callMsg.Gas, callMsg.GasPrice, callMsg.Data)
+	tx, err := buildTransaction(client, callMsg, nonce, chainId, opts)
+	if err != nil {
+		return common.Hash{}, common.Address{}, err
 	}
 	// Sign transaction
-	tx, err = keystore.SignTxWithPassphrase(accounts.Account{Address: callMsg.From}, passphrase, tx, chainId)
+	tx, err = signer.SignTxWithPassphrase(accounts.Account{Address: callMsg.From}, passphrase, tx, chainId)
 	if err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, common.Address{}, err
 	}
 
 	// Send transaction
 	timeoutContext, _ := makeTimeoutContext(5 * time.Second)
 	if err := client.Cli.SendTransaction(timeoutContext, tx); err != nil {
-		return common.Hash{}, err
+		return common.Hash{}, common.Address{}, err
 	}
 	logger.Noticef("sendTransaction, hash=%s", tx.Hash().Hex())
 
+	if opts.replace {
+		if tx, err = replaceTx(client, tx, callMsg, passphrase, signer, opts); err != nil {
+			logger.Error(err)
+		}
+	}
+
 	// Wait for the mining
+	var contractAddr common.Address
 	if wait {
-		timeoutContext, _ := makeTimeoutContext(60 * time.Second)
-		receipt, err := waitMined(timeoutContext, client, tx.Hash())
+		receipt, err := waitMined(context.Background(), client, tx.Hash(), opts.waitTimeout, opts.confirmations)
 		if err != nil {
 			logger.Notice("wait transaction receipt failed")
 		} else {
 			logger.Noticef("transaction receipt=%s", receipt.String())
+			if callMsg.To == nil {
+				if contractAddr, err = waitDeployed(context.Background(), client, receipt); err != nil {
+					logger.Error(err)
+				}
+			}
 		}
 	}
-	return tx.Hash(), nil
+	return tx.Hash(), contractAddr, nil
+}
+
+// waitDeployed confirms that a contract-creation transaction's receipt
+// actually resulted in deployed code, mirroring the ErrNoCodeAfterDeploy
+// check go-ethereum's bind package performs after a deployment.
+func waitDeployed(ctx context.Context, client *client.Client, receipt *types.Receipt) (common.Address, error) {
+	code, err := client.Cli.CodeAt(ctx, receipt.ContractAddress, receipt.BlockNumber)
+	if err != nil {
+		return common.Address{}, err
+	}
+	if len(code) == 0 {
+		return common.Address{}, errNoCodeAfterDeploy
+	}
+	return receipt.ContractAddress, nil
+}
+
+// buildTransaction constructs an unsigned transaction of the requested type.
+// For txTypeAuto it falls back to a dynamic-fee transaction whenever the
+// connected node reports a base fee, and to legacy otherwise.
+func buildTransaction(client *client.Client, callMsg *ethereum.CallMsg, nonce uint64, chainId *big.Int, opts *txOptions) (*types.Transaction, error) {
+	txType := opts.txType
+	var baseFee *big.Int
+	if txType == txTypeDynamic || txType == txTypeAuto {
+		var err error
+		baseFee, err = latestBaseFee(client)
+		if err != nil {
+			if txType == txTypeDynamic {
+				return nil, err
+			}
+			txType = txTypeLegacy
+		} else if baseFee == nil {
+			if txType == txTypeAuto {
+				txType = txTypeLegacy
+			} else if opts.feeCap == nil {
+				// dynamic was requested explicitly but the chain doesn't
+				// implement EIP-1559, and there's no explicit fee cap to
+				// fall back on, so dynamicFeeCaps has nothing to compute
+				// maxFeePerGas from.
+				return nil, errNoBaseFee
+			}
+		}
+	}
+	if txType != txTypeDynamic && txType != txTypeAuto {
+		gasPrice := callMsg.GasPrice
+		if opts.gasPrice != nil {
+			gasPrice = opts.gasPrice
+		}
+		if callMsg.To == nil {
+			return types.NewContractCreation(nonce, callMsg.Value, callMsg.Gas, gasPrice, callMsg.Data), nil
+		}
+		return types.NewTransaction(nonce, *callMsg.To, callMsg.Value, callMsg.Gas, gasPrice, callMsg.Data), nil
+	}
+
+	tipCap, feeCap, err := dynamicFeeCaps(client, baseFee, opts.tipCap, opts.feeCap)
+	if err != nil {
+		return nil, err
+	}
+	return types.NewTx(&types.DynamicFeeTx{
+		ChainID:   chainId,
+		Nonce:     nonce,
+		GasTipCap: tipCap,
+		GasFeeCap: feeCap,
+		Gas:       callMsg.Gas,
+		To:        callMsg.To,
+		Value:     callMsg.Value,
+		Data:      callMsg.Data,
+	}), nil
+}
+
+// latestBaseFee returns the base fee of the latest block, or nil if the
+// connected chain does not implement EIP-1559.
+func latestBaseFee(client *client.Client) (*big.Int, error) {
+	timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+	header, err := client.Cli.HeaderByNumber(timeoutContext, nil)
+	if err != nil {
+		return nil, err
+	}
+	return header.BaseFee, nil
+}
+
+// dynamicFeeCaps resolves the tip cap and fee cap to use for a dynamic-fee
+// transaction, falling back to the node's suggested tip cap and the
+// recommended maxFeePerGas = 2*baseFee + tip when the caller doesn't
+// override them.
+func dynamicFeeCaps(client *client.Client, baseFee, tipCap, feeCap *big.Int) (*big.Int, *big.Int, error) {
+	if tipCap == nil {
+		timeoutContext, _ := makeTimeoutContext(5 * time.Second)
+		suggested, err := client.Cli.SuggestGasTipCap(timeoutContext)
+		if err != nil {
+			return nil, nil, err
+		}
+		tipCap = suggested
+	}
+	if feeCap == nil {
+		feeCap = new(big.Int).Add(new(big.Int).Mul(big.NewInt(2), baseFee), tipCap)
+	}
+	return tipCap, feeCap, nil
+}
+
+// replaceTx watches the pool for the given transaction and, if it isn't
+// mined within opts.replaceInterval, rebroadcasts it with the same nonce and
+// bumped fees (tip/fee caps, or gas price for a legacy transaction; see
+// bumpedTxOptions) until it is mined or opts.replaceTimeout elapses.
+func replaceTx(client *client.Client, tx *types.Transaction, callMsg *ethereum.CallMsg, passphrase string, signer Signer, opts *txOptions) (*types.Transaction, error) {
+	deadline := monoNow() + int64(opts.replaceTimeout)
+	for {
+		receipt, err := waitMined(context.Background(), client, tx.Hash(), opts.replaceInterval, opts.confirmations)
+		if err == nil {
+			logger.Noticef("transaction mined before replacement, hash=%s, receipt=%s", tx.Hash().Hex(), receipt.String())
+			return tx, nil
+		}
+		if monoNow() >= deadline {
+			return tx, errWaitTimeout
+		}
+
+		bumped, err := bumpedTxOptions(client, tx, opts)
+		if err != nil {
+			return tx, err
+		}
+		nonce, chainId := tx.Nonce(), tx.ChainId()
+		next, err := buildTransaction(client, callMsg, nonce, chainId, bumped)
+		if err != nil {
+			return tx, err
+		}
+		next, err = signer.SignTxWithPassphrase(accounts.Account{Address: callMsg.From}, passphrase, next, chainId)
+		if err != nil {
+			return tx, err
+		}
+		sendContext, _ := makeTimeoutContext(5 * time.Second)
+		if err := client.Cli.SendTransaction(sendContext, next); err != nil {
+			return tx, err
+		}
+		logger.Noticef("replaceTx rebroadcast, oldHash=%s, newHash=%s", tx.Hash().Hex(), next.Hash().Hex())
+		tx = next
+	}
+}
+
+// bumpedTxOptions returns a copy of opts whose fee fields are tx's own fees
+// bumped by opts.replaceBumpPct percent, so each rebroadcast outbids the one
+// before it, preserving the tx type the original send
+// actually resolved to: a legacy transaction is rebroadcast as legacy with
+// a bumped gas price, never upgraded to a type-2 transaction a pre-London
+// node would simply reject.
+func bumpedTxOptions(client *client.Client, tx *types.Transaction, opts *txOptions) (*txOptions, error) {
+	bumped := *opts
+	if tx.Type() != types.DynamicFeeTxType {
+		bumped.txType = txTypeLegacy
+		bumped.gasPrice = bumpByPercent(tx.GasPrice(), opts.replaceBumpPct)
+		return &bumped, nil
+	}
+
+	bumped.txType = txTypeDynamic
+	tipCap, feeCap := tx.GasTipCap(), tx.GasFeeCap()
+	if tipCap == nil || tipCap.Sign() == 0 {
+		baseFee, err := latestBaseFee(client)
+		if err != nil {
+			return nil, err
+		}
+		tipCap, feeCap, err = dynamicFeeCaps(client, baseFee, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+	}
+	bumped.tipCap = bumpByPercent(tipCap, opts.replaceBumpPct)
+	bumped.feeCap = bumpByPercent(feeCap, opts.replaceBumpPct)
+	return &bumped, nil
+}
+
+// bumpByPercent returns value increased by pct percent, rounding down.
+func bumpByPercent(value *big.Int, pct int64) *big.Int {
+	increase := new(big.Int).Mul(value, big.NewInt(pct))
+	increase.Div(increase, big.NewInt(100))
+	return new(big.Int).Add(value, increase)
 }
 
 // fetchParams returns estimated gas limit, suggested gas price and sender pending nonce.
@@ -286,20 +719,109 @@ func fetchParams(client *client.Client, callMsg *ethereum.CallMsg) (*big.Int, ui
 	return gasPrice, gasLimit, nonce, chainId, nil
 }
 
-// waitMined waits the transaction been mined and fetch the receipt.
-// An error will been returned if waiting exceeds the given timeout
-func waitMined(ctx context.Context, client *client.Client, txHash common.Hash) (*types.Receipt, error) {
+// waitMinedPollInterval and waitMinedMaxPollInterval bound the exponential
+// backoff used once waitMined falls back to polling.
+const (
+	waitMinedPollInterval    = 100 * time.Millisecond
+	waitMinedMaxPollInterval = 2 * time.Second
+)
+
+// waitMined waits for a transaction to reach confirmations block
+// confirmations, preferring a head subscription (one TransactionReceipt
+// call per new block) and falling back to polling with exponential backoff
+// when the endpoint is HTTP-only or the subscription errors. The deadline
+// is tracked with a monotonic clock so an NTP step during a long wait can't
+// cause a premature errWaitTimeout or silently extend it.
+func waitMined(ctx context.Context, client *client.Client, txHash common.Hash, timeout time.Duration, confirmations uint64) (*types.Receipt, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	deadline := monoNow() + int64(timeout)
+
+	headCh := make(chan *types.Header)
+	sub, err := client.Cli.SubscribeNewHead(ctx, headCh)
+	if err != nil {
+		return waitMinedPoll(ctx, client, txHash, deadline, confirmations)
+	}
+	defer sub.Unsubscribe()
+
+	// deadlineTimer wakes the select even if the node goes quiet and never
+	// delivers another head event before the deadline passes.
+	deadlineTimer := time.NewTimer(time.Duration(deadline - monoNow()))
+	defer deadlineTimer.Stop()
+
+	for {
+		select {
+		case err := <-sub.Err():
+			logger.Error(err)
+			return waitMinedPoll(ctx, client, txHash, deadline, confirmations)
+		case <-deadlineTimer.C:
+			return nil, errWaitTimeout
+		case head := <-headCh:
+			receipt, mined, err := checkMined(ctx, client, txHash, head.Number, confirmations)
+			if err != nil {
+				return nil, err
+			}
+			if mined {
+				return receipt, nil
+			}
+		}
+		if monoNow() >= deadline {
+			return nil, errWaitTimeout
+		}
+	}
+}
+
+// waitMinedPoll polls TransactionReceipt with exponential backoff between
+// waitMinedPollInterval and waitMinedMaxPollInterval until the transaction
+// reaches the required confirmations or the monotonic deadline passes.
+func waitMinedPoll(ctx context.Context, client *client.Client, txHash common.Hash, deadline int64, confirmations uint64) (*types.Receipt, error) {
+	backoff := waitMinedPollInterval
 	for {
-		receipt, err := client.Cli.TransactionReceipt(ctx, txHash)
-		if receipt == nil || err != nil {
-			time.Sleep(1 * time.Second)
-		} else {
+		receipt, mined, err := checkMined(ctx, client, txHash, nil, confirmations)
+		if err != nil {
+			return nil, err
+		}
+		if mined {
 			return receipt, nil
 		}
+		if monoNow() >= deadline {
+			return nil, errWaitTimeout
+		}
 		select {
 		case <-ctx.Done():
 			return nil, errWaitTimeout
-		default:
+		case <-time.After(backoff):
+		}
+		if backoff *= 2; backoff > waitMinedMaxPollInterval {
+			backoff = waitMinedMaxPollInterval
+		}
+	}
+}
+
+// checkMined fetches the receipt for txHash and reports whether it has
+// reached the required confirmations. headNumber is the latest known block
+// number; if nil, it is fetched on demand (only needed when confirmations
+// is greater than one).
+func checkMined(ctx context.Context, client *client.Client, txHash common.Hash, headNumber *big.Int, confirmations uint64) (*types.Receipt, bool, error) {
+	receipt, err := client.Cli.TransactionReceipt(ctx, txHash)
+	if err != nil || receipt == nil {
+		return nil, false, nil
+	}
+	if confirmations <= 1 {
+		return receipt, true, nil
+	}
+	if headNumber == nil {
+		header, err := client.Cli.HeaderByNumber(ctx, nil)
+		if err != nil {
+			return nil, false, err
 		}
+		headNumber = header.Number
+	}
+	confirmed := new(big.Int).Sub(headNumber, receipt.BlockNumber)
+	confirmed.Add(confirmed, big.NewInt(1))
+	if confirmed.Cmp(new(big.Int).SetUint64(confirmations)) < 0 {
+		return nil, false, nil
 	}
+	return receipt, true, nil
 }
@@ -0,0 +1,135 @@
+// Copyright 2016-2017 Hyperchain Corp.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//    http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var errSignerFlagConflict = errors.New("--signer.external is mutually exclusive with --keystore/--passphrase")
+
+// Signer abstracts transaction signing so sendTransaction doesn't need to
+// care whether the key material lives in a local keystore or behind a
+// remote Clef-style signer.
+type Signer interface {
+	SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error)
+}
+
+// keystoreSigner adapts a local keystore.KeyStore to the Signer interface.
+type keystoreSigner struct {
+	ks *keystore.KeyStore
+}
+
+func newKeystoreSigner(ks *keystore.KeyStore) *keystoreSigner {
+	return &keystoreSigner{ks: ks}
+}
+
+func (s *keystoreSigner) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	return s.ks.SignTxWithPassphrase(account, passphrase, tx, chainId)
+}
+
+// ExternalSigner talks to a Clef-style external signer over JSON-RPC
+// (IPC or HTTPS), so operators running batches against production networks
+// never need plaintext passphrases on disk.
+type ExternalSigner struct {
+	client *rpc.Client
+}
+
+// NewExternalSigner dials the given Clef-style endpoint. Clef's own
+// account_signTransaction has no rules parameter: a ruleset is configured
+// when Clef itself is started, not per request.
+func NewExternalSigner(endpoint string) (*ExternalSigner, error) {
+	client, err := rpc.Dial(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	return &ExternalSigner{client: client}, nil
+}
+
+// signTransactionResult mirrors the result shape of Clef's
+// account_signTransaction: the RLP-encoded signed transaction plus a
+// decoded view of it.
+type signTransactionResult struct {
+	Raw hexutil.Bytes      `json:"raw"`
+	Tx  *types.Transaction `json:"tx"`
+}
+
+// SignTxWithPassphrase asks the external signer to sign tx on behalf of
+// account. The passphrase is ignored: Clef-style signers authenticate and
+// authorize the request themselves, typically by prompting the operator or
+// evaluating their own configured ruleset.
+func (s *ExternalSigner) SignTxWithPassphrase(account accounts.Account, passphrase string, tx *types.Transaction, chainId *big.Int) (*types.Transaction, error) {
+	args := toSendTxArgs(account.Address, tx, chainId)
+	var result signTransactionResult
+	if err := s.client.Call(&result, "account_signTransaction", args); err != nil {
+		return nil, err
+	}
+	return result.Tx, nil
+}
+
+// toSendTxArgs translates an already-built transaction into the argument
+// shape account_signTransaction expects, so the external signer signs
+// exactly the fields we estimated rather than re-deriving them itself.
+func toSendTxArgs(from common.Address, tx *types.Transaction, chainId *big.Int) *apitypes.SendTxArgs {
+	data := hexutil.Bytes(tx.Data())
+	args := &apitypes.SendTxArgs{
+		From:  common.NewMixedcaseAddress(from),
+		Gas:   hexutil.Uint64(tx.Gas()),
+		Value: hexutil.Big(*tx.Value()),
+		Nonce: hexutil.Uint64(tx.Nonce()),
+		Data:  &data,
+	}
+	if tx.Type() == types.DynamicFeeTxType {
+		maxFeePerGas := hexutil.Big(*tx.GasFeeCap())
+		maxPriorityFeePerGas := hexutil.Big(*tx.GasTipCap())
+		args.MaxFeePerGas = &maxFeePerGas
+		args.MaxPriorityFeePerGas = &maxPriorityFeePerGas
+	} else {
+		args.GasPrice = (*hexutil.Big)(tx.GasPrice())
+	}
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	if chainId != nil {
+		id := hexutil.Big(*chainId)
+		args.ChainID = &id
+	}
+	return args
+}
+
+// getSigner builds the Signer the current command should use, rejecting
+// the flag combination of an external signer alongside local keystore
+// credentials.
+func getSigner(ctx *cli.Context) (Signer, error) {
+	external := ctx.String(signerExternalFlag.Name)
+	if external == "" {
+		return newKeystoreSigner(getKeystore(ctx)), nil
+	}
+	if ctx.String(keystoreFlag.Name) != "" || ctx.String(passphraseFlag.Name) != "" || ctx.String(passphraseFileFlag.Name) != "" {
+		return nil, errSignerFlagConflict
+	}
+	return NewExternalSigner(external)
+}